@@ -0,0 +1,17 @@
+package cache
+
+import (
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// IndexFunc knows how to compute the set of indexed values for an object.
+type IndexFunc func(obj any) ([]string, error)
+
+// Index maps the indexed value to a set of keys in the store that match on that value.
+type Index map[string]sets.String
+
+// Indexers maps a name to an IndexFunc.
+type Indexers map[string]IndexFunc
+
+// Indices maps a name to an Index.
+type Indices map[string]Index
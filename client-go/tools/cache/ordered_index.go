@@ -0,0 +1,212 @@
+package cache
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/google/btree"
+)
+
+// OrderedIndexFunc knows how to compute the indexed value for an object that
+// should be kept in sorted order. Unlike IndexFunc, it returns a single
+// value per object: an object that maps to more than one ordered value
+// cannot be placed at more than one position in the tree.
+type OrderedIndexFunc func(obj any) (string, error)
+
+// orderedIndexEntry is the unit stored in an ordered index's B-tree. Entries
+// are ordered by indexedValue first and storeKey second so that objects
+// sharing the same indexed value still have a well defined, stable order.
+type orderedIndexEntry struct {
+	indexedValue string
+	storeKey     string
+}
+
+func orderedIndexEntryLess(a, b orderedIndexEntry) bool {
+	if a.indexedValue != b.indexedValue {
+		return a.indexedValue < b.indexedValue
+	}
+	return a.storeKey < b.storeKey
+}
+
+// orderedIndex holds the current snapshot of a single ordered indexer's
+// B-tree behind an atomic.Value so that Ascend/Descend/Range callers can
+// walk it without holding the store's write lock.
+type orderedIndex struct {
+	tree atomic.Value // holds *btree.BTreeG[orderedIndexEntry]
+}
+
+func newOrderedIndex() *orderedIndex {
+	oi := &orderedIndex{}
+	oi.tree.Store(btree.NewG[orderedIndexEntry](32, orderedIndexEntryLess))
+	return oi
+}
+
+func (oi *orderedIndex) load() *btree.BTreeG[orderedIndexEntry] {
+	return oi.tree.Load().(*btree.BTreeG[orderedIndexEntry])
+}
+
+// mutate clones the current tree (a cheap copy-on-write operation), applies
+// fn to the clone, and publishes it. Callers must hold the store's write
+// lock so that concurrent mutations are serialized.
+func (oi *orderedIndex) mutate(fn func(t *btree.BTreeG[orderedIndexEntry]) *btree.BTreeG[orderedIndexEntry]) {
+	oi.tree.Store(fn(oi.load().Clone()))
+}
+
+func (i *storeIndex) addOrderedIndexers(newIndexers map[string]OrderedIndexFunc) error {
+	if i.orderedIndexers == nil {
+		i.orderedIndexers = map[string]OrderedIndexFunc{}
+	}
+	if i.orderedIndices == nil {
+		i.orderedIndices = map[string]*orderedIndex{}
+	}
+
+	for name := range newIndexers {
+		if _, exists := i.orderedIndexers[name]; exists {
+			return fmt.Errorf("ordered indexer conflict: %s", name)
+		}
+	}
+
+	for name, indexFunc := range newIndexers {
+		i.orderedIndexers[name] = indexFunc
+		i.orderedIndices[name] = newOrderedIndex()
+	}
+	return nil
+}
+
+// updateOrderedIndices mirrors storeIndex.updateIndices: it removes the
+// stale (value, key) entry for each ordered indexer and inserts the new one.
+func (i *storeIndex) updateOrderedIndices(oldObj any, newObj any, key string) {
+	for name, indexFunc := range i.orderedIndexers {
+		var oldValue, newValue string
+		var err error
+
+		if oldObj != nil {
+			oldValue, err = indexFunc(oldObj)
+			if err != nil {
+				panic(fmt.Errorf("unable to calculate an ordered index entry for key %q on index %q: %v", key, name, err))
+			}
+		}
+		if newObj != nil {
+			newValue, err = indexFunc(newObj)
+			if err != nil {
+				panic(fmt.Errorf("unable to calculate an ordered index entry for key %q on index %q: %v", key, name, err))
+			}
+		}
+
+		if oldObj != nil && newObj != nil && oldValue == newValue {
+			continue
+		}
+
+		index := i.orderedIndices[name]
+		index.mutate(func(t *btree.BTreeG[orderedIndexEntry]) *btree.BTreeG[orderedIndexEntry] {
+			if oldObj != nil {
+				t.Delete(orderedIndexEntry{indexedValue: oldValue, storeKey: key})
+			}
+			if newObj != nil {
+				t.ReplaceOrInsert(orderedIndexEntry{indexedValue: newValue, storeKey: key})
+			}
+			return t
+		})
+	}
+}
+
+func (i *storeIndex) resetOrdered() {
+	for name := range i.orderedIndices {
+		i.orderedIndices[name] = newOrderedIndex()
+	}
+}
+
+func (c *threadSafeMap) AddOrderedIndexers(newIndexers map[string]OrderedIndexFunc) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if len(c.items) > 0 {
+		return fmt.Errorf("cannot add ordered indexers to running index")
+	}
+
+	return c.index.addOrderedIndexers(newIndexers)
+}
+
+// RangeByIndex returns, in ascending order, every object whose ordered
+// indexed value falls within [lo, hi].
+func (c *threadSafeMap) RangeByIndex(indexName, lo, hi string) ([]any, error) {
+	c.lock.RLock()
+	index, ok := c.index.orderedIndices[indexName]
+	if !ok {
+		c.lock.RUnlock()
+		return nil, fmt.Errorf("ordered index with name %s does not exist", indexName)
+	}
+	tree := index.load()
+	c.lock.RUnlock()
+
+	var keys []string
+	tree.AscendGreaterOrEqual(
+		orderedIndexEntry{indexedValue: lo},
+		func(e orderedIndexEntry) bool {
+			if e.indexedValue > hi {
+				return false
+			}
+			keys = append(keys, e.storeKey)
+			return true
+		},
+	)
+
+	result := make([]any, 0, len(keys))
+	for _, key := range keys {
+		c.lock.RLock()
+		obj, exists := c.items[key]
+		c.lock.RUnlock()
+		if exists {
+			result = append(result, obj)
+		}
+	}
+	return result, nil
+}
+
+// AscendByIndex walks the ordered index in ascending order starting at
+// pivot, invoking fn for each (key, object) pair until fn returns false.
+func (c *threadSafeMap) AscendByIndex(indexName, pivot string, fn func(key string, obj any) bool) error {
+	c.lock.RLock()
+	index, ok := c.index.orderedIndices[indexName]
+	if !ok {
+		c.lock.RUnlock()
+		return fmt.Errorf("ordered index with name %s does not exist", indexName)
+	}
+	tree := index.load()
+	c.lock.RUnlock()
+
+	tree.AscendGreaterOrEqual(orderedIndexEntry{indexedValue: pivot}, func(e orderedIndexEntry) bool {
+		c.lock.RLock()
+		obj, exists := c.items[e.storeKey]
+		c.lock.RUnlock()
+		if !exists {
+			return true
+		}
+		return fn(e.storeKey, obj)
+	})
+	return nil
+}
+
+// DescendByIndex walks the ordered index in descending order starting at
+// pivot, invoking fn for each (key, object) pair until fn returns false.
+func (c *threadSafeMap) DescendByIndex(indexName, pivot string, fn func(key string, obj any) bool) error {
+	c.lock.RLock()
+	index, ok := c.index.orderedIndices[indexName]
+	if !ok {
+		c.lock.RUnlock()
+		return fmt.Errorf("ordered index with name %s does not exist", indexName)
+	}
+	tree := index.load()
+	c.lock.RUnlock()
+
+	tree.DescendLessOrEqual(orderedIndexEntry{indexedValue: pivot, storeKey: "\xff"}, func(e orderedIndexEntry) bool {
+		c.lock.RLock()
+		obj, exists := c.items[e.storeKey]
+		c.lock.RUnlock()
+		if !exists {
+			return true
+		}
+		return fn(e.storeKey, obj)
+	})
+	return nil
+}
@@ -0,0 +1,131 @@
+package cache
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newOrderedTestStore(t *testing.T) *threadSafeMap {
+	t.Helper()
+	store := NewThreadSafeStore(Indexers{}, Indices{}).(*threadSafeMap)
+	err := store.AddOrderedIndexers(map[string]OrderedIndexFunc{
+		"byValue": func(obj any) (string, error) {
+			return obj.(string), nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("AddOrderedIndexers: %v", err)
+	}
+	return store
+}
+
+func TestOrderedIndexMonotonicInserts(t *testing.T) {
+	store := newOrderedTestStore(t)
+
+	for i := 0; i < 10; i++ {
+		key := "key" + strconv.Itoa(i)
+		store.Add(key, "v"+strconv.Itoa(i))
+	}
+
+	var seen []string
+	err := store.AscendByIndex("byValue", "", func(key string, obj any) bool {
+		seen = append(seen, obj.(string))
+		return true
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"v0", "v1", "v2", "v3", "v4", "v5", "v6", "v7", "v8", "v9"}, seen)
+}
+
+func TestOrderedIndexUpdateMovesKeyAcrossValues(t *testing.T) {
+	store := newOrderedTestStore(t)
+
+	store.Add("a", "bravo")
+	store.Add("b", "delta")
+	store.Add("c", "alpha")
+
+	results, err := store.RangeByIndex("byValue", "alpha", "delta")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []any{"alpha", "bravo", "delta"}, results)
+
+	store.Update("c", "zulu")
+
+	results, err = store.RangeByIndex("byValue", "alpha", "delta")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []any{"bravo", "delta"}, results)
+
+	results, err = store.RangeByIndex("byValue", "zulu", "zulu")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []any{"zulu"}, results)
+
+	store.Delete("a")
+	results, err = store.RangeByIndex("byValue", "alpha", "zulu")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []any{"delta", "zulu"}, results)
+}
+
+func TestOrderedIndexRangeExcludesStrictPrefixMatches(t *testing.T) {
+	store := newOrderedTestStore(t)
+
+	store.Add("a", "a")
+	store.Add("ab", "ab")
+	store.Add("b", "b")
+
+	results, err := store.RangeByIndex("byValue", "a", "a")
+	assert.NoError(t, err)
+	assert.Equal(t, []any{"a"}, results)
+}
+
+func TestOrderedIndexDescendByIndex(t *testing.T) {
+	store := newOrderedTestStore(t)
+
+	store.Add("a", "alpha")
+	store.Add("b", "bravo")
+	store.Add("c", "charlie")
+
+	var seen []string
+	err := store.DescendByIndex("byValue", "zzz", func(key string, obj any) bool {
+		seen = append(seen, obj.(string))
+		return true
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"charlie", "bravo", "alpha"}, seen)
+}
+
+func TestOrderedIndexConcurrentAscendAndUpdate(t *testing.T) {
+	store := newOrderedTestStore(t)
+
+	const items = 200
+	for i := 0; i < items; i++ {
+		store.Add("key"+strconv.Itoa(i), "v"+strconv.Itoa(i))
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+		for i := 0; i < items; i++ {
+			store.Update("key"+strconv.Itoa(i), "v"+strconv.Itoa(i)+"-updated")
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			count := 0
+			_ = store.AscendByIndex("byValue", "", func(key string, obj any) bool {
+				count++
+				return true
+			})
+		}
+	}()
+
+	wg.Wait()
+
+	results, err := store.RangeByIndex("byValue", "", "v9999")
+	assert.NoError(t, err)
+	assert.Len(t, results, items)
+}
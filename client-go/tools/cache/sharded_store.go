@@ -0,0 +1,473 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"sort"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// shardedThreadSafeStore partitions items across N independent shards, each
+// a regular threadSafeMap with its own RWMutex and item map, keyed by
+// fnv(key) % N. This trades the single global lock that threadSafeMap.Update
+// holds across every write and index recomputation for N smaller locks, so
+// writers to different shards no longer contend with each other.
+//
+// Indices are maintained per-shard and merged on read: Index/ByIndex union
+// the per-shard results, and ordered range queries merge-sort the per-shard
+// results by the indexed value. Replace must take every shard's write lock
+// in a fixed, ascending order before mutating any of them, so it can never
+// deadlock against another sharded operation.
+type shardedThreadSafeStore struct {
+	shardCount int
+	shards     []*threadSafeMap
+
+	orderedMu       sync.RWMutex
+	orderedIndexers map[string]OrderedIndexFunc
+}
+
+// NewShardedThreadSafeStore builds a ThreadSafeStore that stripes writes
+// across shardCount independent shards. indices is accepted for interface
+// symmetry with NewThreadSafeStore but, since pre-built indices can't be
+// partitioned without knowing which shard each key belongs to, callers are
+// expected to pass an empty Indices{} and let updateIndices populate things
+// as items are added.
+func NewShardedThreadSafeStore(indexers Indexers, indices Indices, shardCount int) ThreadSafeStore {
+	if shardCount < 1 {
+		shardCount = 1
+	}
+	shards := make([]*threadSafeMap, shardCount)
+	for i := range shards {
+		shards[i] = NewThreadSafeStore(copyIndexers(indexers), Indices{}).(*threadSafeMap)
+	}
+	return &shardedThreadSafeStore{shardCount: shardCount, shards: shards}
+}
+
+// copyIndexers returns a shallow copy of indexers so that each shard gets
+// its own storeIndex.indexers map instead of all shards sharing (and racing
+// on) a single one guarded by different shards' locks.
+func copyIndexers(indexers Indexers) Indexers {
+	out := make(Indexers, len(indexers))
+	for name, indexFunc := range indexers {
+		out[name] = indexFunc
+	}
+	return out
+}
+
+func (s *shardedThreadSafeStore) shardFor(key string) *threadSafeMap {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return s.shards[h.Sum32()%uint32(s.shardCount)]
+}
+
+func (s *shardedThreadSafeStore) Add(key string, obj any) {
+	s.shardFor(key).Add(key, obj)
+}
+
+func (s *shardedThreadSafeStore) Update(key string, obj any) {
+	s.shardFor(key).Update(key, obj)
+}
+
+func (s *shardedThreadSafeStore) Delete(key string) {
+	s.shardFor(key).Delete(key)
+}
+
+func (s *shardedThreadSafeStore) Get(key string) (item any, exists bool) {
+	return s.shardFor(key).Get(key)
+}
+
+func (s *shardedThreadSafeStore) List() []any {
+	list := make([]any, 0)
+	for _, shard := range s.shards {
+		list = append(list, shard.List()...)
+	}
+	return list
+}
+
+func (s *shardedThreadSafeStore) ListKeys() []string {
+	keys := make([]string, 0)
+	for _, shard := range s.shards {
+		keys = append(keys, shard.ListKeys()...)
+	}
+	return keys
+}
+
+// Replace acquires every shard's write lock, in ascending shard order, then
+// re-partitions items across shards and swaps each shard's contents in
+// place. Taking all the locks up front in a fixed order is what keeps this
+// safe from deadlocking against a concurrent Replace or a cross-shard read
+// that also walks the shards in order.
+func (s *shardedThreadSafeStore) Replace(items map[string]any, resourceVersion string) {
+	partitioned := make([]map[string]any, s.shardCount)
+	for i := range partitioned {
+		partitioned[i] = map[string]any{}
+	}
+	for key, obj := range items {
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(key))
+		idx := h.Sum32() % uint32(s.shardCount)
+		partitioned[idx][key] = obj
+	}
+
+	for _, shard := range s.shards {
+		shard.lock.Lock()
+	}
+	defer func() {
+		for _, shard := range s.shards {
+			shard.lock.Unlock()
+		}
+	}()
+
+	for i, shard := range s.shards {
+		shard.replaceLocked(partitioned[i])
+		shard.resourceVersion = resourceVersion
+	}
+}
+
+// Snapshot writes a single header covering every shard, followed by every
+// shard's items, using shard 0's Codec. All shards are read-locked for the
+// duration so the snapshot is a consistent point-in-time view.
+func (s *shardedThreadSafeStore) Snapshot(w io.Writer) error {
+	for _, shard := range s.shards {
+		shard.lock.RLock()
+	}
+	defer func() {
+		for _, shard := range s.shards {
+			shard.lock.RUnlock()
+		}
+	}()
+
+	itemCount := 0
+	for _, shard := range s.shards {
+		itemCount += len(shard.items)
+	}
+
+	codec := s.shards[0].codec
+	header := snapshotHeader{
+		SchemaVersion:   snapshotSchemaVersion,
+		ItemCount:       itemCount,
+		IndexerNames:    sortedIndexerNames(s.shards[0].index.indexers),
+		ResourceVersion: s.shards[0].resourceVersion,
+	}
+	enc := codec.NewEncoder(w)
+	if err := enc.Encode(header); err != nil {
+		return fmt.Errorf("encoding snapshot header: %w", err)
+	}
+
+	for _, shard := range s.shards {
+		for key, obj := range shard.items {
+			record := snapshotRecord{Key: key, Obj: obj}
+			if err := enc.Encode(record); err != nil {
+				return fmt.Errorf("encoding snapshot record for key %q: %w", key, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Restore reads a snapshot written by Snapshot, re-partitions its items
+// across shards and installs them under every shard's write lock, taken in
+// the same fixed ascending order Replace uses.
+func (s *shardedThreadSafeStore) Restore(r io.Reader) error {
+	codec := s.shards[0].codec
+
+	dec := codec.NewDecoder(r)
+	var header snapshotHeader
+	if err := dec.Decode(&header); err != nil {
+		return fmt.Errorf("decoding snapshot header: %w", err)
+	}
+	if header.SchemaVersion != snapshotSchemaVersion {
+		return fmt.Errorf("unsupported snapshot schema version %d", header.SchemaVersion)
+	}
+
+	indexerNames := sortedIndexerNames(s.shards[0].index.indexers)
+	if !equalStringSlices(indexerNames, header.IndexerNames) {
+		return fmt.Errorf("snapshot indexers %v do not match store indexers %v", header.IndexerNames, indexerNames)
+	}
+
+	partitioned := make([]map[string]any, s.shardCount)
+	for i := range partitioned {
+		partitioned[i] = map[string]any{}
+	}
+	for i := 0; i < header.ItemCount; i++ {
+		var record snapshotRecord
+		if err := dec.Decode(&record); err != nil {
+			return fmt.Errorf("decoding snapshot record %d: %w", i, err)
+		}
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(record.Key))
+		idx := h.Sum32() % uint32(s.shardCount)
+		partitioned[idx][record.Key] = record.Obj
+	}
+
+	for _, shard := range s.shards {
+		shard.lock.Lock()
+	}
+	defer func() {
+		for _, shard := range s.shards {
+			shard.lock.Unlock()
+		}
+	}()
+
+	for i, shard := range s.shards {
+		shard.replaceLocked(partitioned[i])
+		shard.resourceVersion = header.ResourceVersion
+	}
+	return nil
+}
+
+func (s *shardedThreadSafeStore) Index(indexName string, obj any) ([]any, error) {
+	all := make([]any, 0)
+	for _, shard := range s.shards {
+		items, err := shard.Index(indexName, obj)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, items...)
+	}
+	return all, nil
+}
+
+func (s *shardedThreadSafeStore) IndexKeys(indexName, indexedValue string) ([]string, error) {
+	all := make([]string, 0)
+	for _, shard := range s.shards {
+		keys, err := shard.IndexKeys(indexName, indexedValue)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, keys...)
+	}
+	return all, nil
+}
+
+func (s *shardedThreadSafeStore) ListIndexFuncValues(indexName string) []string {
+	values := sets.String{}
+	for _, shard := range s.shards {
+		values.Insert(shard.ListIndexFuncValues(indexName)...)
+	}
+	return values.List()
+}
+
+func (s *shardedThreadSafeStore) ByIndex(indexName, indexedValue string) ([]any, error) {
+	all := make([]any, 0)
+	for _, shard := range s.shards {
+		items, err := shard.ByIndex(indexName, indexedValue)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, items...)
+	}
+	return all, nil
+}
+
+func (s *shardedThreadSafeStore) GetIndexers() Indexers {
+	return s.shards[0].GetIndexers()
+}
+
+func (s *shardedThreadSafeStore) AddIndexers(newIndexers Indexers) error {
+	for _, shard := range s.shards {
+		if err := shard.AddIndexers(copyIndexers(newIndexers)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *shardedThreadSafeStore) Resync() error {
+	for _, shard := range s.shards {
+		if err := shard.Resync(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *shardedThreadSafeStore) AddOrderedIndexers(newIndexers map[string]OrderedIndexFunc) error {
+	for _, shard := range s.shards {
+		if err := shard.AddOrderedIndexers(newIndexers); err != nil {
+			return err
+		}
+	}
+
+	s.orderedMu.Lock()
+	defer s.orderedMu.Unlock()
+	if s.orderedIndexers == nil {
+		s.orderedIndexers = map[string]OrderedIndexFunc{}
+	}
+	for name, fn := range newIndexers {
+		s.orderedIndexers[name] = fn
+	}
+	return nil
+}
+
+func (s *shardedThreadSafeStore) orderedIndexFunc(indexName string) (OrderedIndexFunc, error) {
+	s.orderedMu.RLock()
+	defer s.orderedMu.RUnlock()
+	fn, ok := s.orderedIndexers[indexName]
+	if !ok {
+		return nil, fmt.Errorf("ordered index with name %s does not exist", indexName)
+	}
+	return fn, nil
+}
+
+// RangeByIndex collects each shard's matching range and merge-sorts the
+// results by indexed value, since no single shard's B-tree spans the full
+// keyspace.
+func (s *shardedThreadSafeStore) RangeByIndex(indexName, lo, hi string) ([]any, error) {
+	valueFn, err := s.orderedIndexFunc(indexName)
+	if err != nil {
+		return nil, err
+	}
+
+	all := make([]any, 0)
+	for _, shard := range s.shards {
+		items, err := shard.RangeByIndex(indexName, lo, hi)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, items...)
+	}
+
+	sort.Slice(all, func(i, j int) bool {
+		vi, _ := valueFn(all[i])
+		vj, _ := valueFn(all[j])
+		return vi < vj
+	})
+	return all, nil
+}
+
+type orderedShardEntry struct {
+	key   string
+	obj   any
+	value string
+}
+
+func (s *shardedThreadSafeStore) AscendByIndex(indexName, pivot string, fn func(key string, obj any) bool) error {
+	valueFn, err := s.orderedIndexFunc(indexName)
+	if err != nil {
+		return err
+	}
+
+	var all []orderedShardEntry
+	for _, shard := range s.shards {
+		err := shard.AscendByIndex(indexName, pivot, func(key string, obj any) bool {
+			value, _ := valueFn(obj)
+			all = append(all, orderedShardEntry{key: key, obj: obj, value: value})
+			return true
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].value != all[j].value {
+			return all[i].value < all[j].value
+		}
+		return all[i].key < all[j].key
+	})
+	for _, e := range all {
+		if !fn(e.key, e.obj) {
+			break
+		}
+	}
+	return nil
+}
+
+func (s *shardedThreadSafeStore) DescendByIndex(indexName, pivot string, fn func(key string, obj any) bool) error {
+	valueFn, err := s.orderedIndexFunc(indexName)
+	if err != nil {
+		return err
+	}
+
+	var all []orderedShardEntry
+	for _, shard := range s.shards {
+		err := shard.DescendByIndex(indexName, pivot, func(key string, obj any) bool {
+			value, _ := valueFn(obj)
+			all = append(all, orderedShardEntry{key: key, obj: obj, value: value})
+			return true
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].value != all[j].value {
+			return all[i].value > all[j].value
+		}
+		return all[i].key > all[j].key
+	})
+	for _, e := range all {
+		if !fn(e.key, e.obj) {
+			break
+		}
+	}
+	return nil
+}
+
+// shardedRegistration unregisters every shard's underlying subscription.
+type shardedRegistration struct {
+	regs []Registration
+}
+
+func (r *shardedRegistration) Unregister() error {
+	var firstErr error
+	for _, reg := range r.regs {
+		if err := reg.Unregister(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Subscribe fans out to every shard; handler may be invoked concurrently
+// from up to shardCount goroutines, so it must be safe for concurrent use.
+func (s *shardedThreadSafeStore) Subscribe(handler ResourceEventHandler) (Registration, error) {
+	regs := make([]Registration, 0, len(s.shards))
+	for _, shard := range s.shards {
+		reg, err := shard.Subscribe(handler)
+		if err != nil {
+			for _, r := range regs {
+				_ = r.Unregister()
+			}
+			return nil, err
+		}
+		regs = append(regs, reg)
+	}
+	return &shardedRegistration{regs: regs}, nil
+}
+
+// Watch fans every shard's channel into a single output channel.
+func (s *shardedThreadSafeStore) Watch(ctx context.Context, filter FilterFunc) (<-chan Event, error) {
+	out := make(chan Event, defaultSubscriberBufferSize)
+	var wg sync.WaitGroup
+
+	for _, shard := range s.shards {
+		ch, err := shard.Watch(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		wg.Add(1)
+		go func(ch <-chan Event) {
+			defer wg.Done()
+			for e := range ch {
+				select {
+				case out <- e:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}(ch)
+	}
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out, nil
+}
@@ -0,0 +1,192 @@
+package cache
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShardedThreadSafeStoreAddGetDelete(t *testing.T) {
+	indexers := Indexers{
+		"byValue": func(obj any) ([]string, error) {
+			return []string{obj.(string)}, nil
+		},
+	}
+	store := NewShardedThreadSafeStore(indexers, Indices{}, 4)
+
+	for i := 0; i < 100; i++ {
+		key := "key" + strconv.Itoa(i)
+		store.Add(key, key)
+	}
+
+	assert.Len(t, store.List(), 100)
+	assert.Len(t, store.ListKeys(), 100)
+
+	obj, exists := store.Get("key42")
+	assert.True(t, exists)
+	assert.Equal(t, "key42", obj)
+
+	items, err := store.ByIndex("byValue", "key42")
+	assert.NoError(t, err)
+	assert.Equal(t, []any{"key42"}, items)
+
+	store.Delete("key42")
+	_, exists = store.Get("key42")
+	assert.False(t, exists)
+	assert.Len(t, store.List(), 99)
+}
+
+func TestShardedThreadSafeStoreReplace(t *testing.T) {
+	store := NewShardedThreadSafeStore(Indexers{}, Indices{}, 4)
+	store.Add("stale", "stale")
+
+	store.Replace(map[string]any{
+		"a": "alpha",
+		"b": "bravo",
+	}, "1")
+
+	assert.ElementsMatch(t, []string{"a", "b"}, store.ListKeys())
+}
+
+func TestShardedThreadSafeStoreOrderedIndex(t *testing.T) {
+	store := NewShardedThreadSafeStore(Indexers{}, Indices{}, 4)
+	err := store.AddOrderedIndexers(map[string]OrderedIndexFunc{
+		"byValue": func(obj any) (string, error) {
+			return obj.(string), nil
+		},
+	})
+	assert.NoError(t, err)
+
+	for _, v := range []string{"delta", "alpha", "charlie", "bravo"} {
+		store.Add(v, v)
+	}
+
+	var seen []string
+	err = store.AscendByIndex("byValue", "", func(key string, obj any) bool {
+		seen = append(seen, obj.(string))
+		return true
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"alpha", "bravo", "charlie", "delta"}, seen)
+}
+
+func TestShardedThreadSafeStoreConcurrentWrites(t *testing.T) {
+	store := NewShardedThreadSafeStore(Indexers{}, Indices{}, 8)
+
+	var wg sync.WaitGroup
+	const writers = 16
+	const perWriter = 200
+	wg.Add(writers)
+	for w := 0; w < writers; w++ {
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				key := fmt.Sprintf("w%d-k%d", w, i)
+				store.Add(key, key)
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	assert.Len(t, store.List(), writers*perWriter)
+}
+
+func TestShardedThreadSafeStoreAddIndexers(t *testing.T) {
+	store := NewShardedThreadSafeStore(Indexers{}, Indices{}, 4)
+
+	err := store.AddIndexers(Indexers{
+		"byValue": func(obj any) ([]string, error) {
+			return []string{obj.(string)}, nil
+		},
+	})
+	assert.NoError(t, err)
+
+	for i := 0; i < 20; i++ {
+		key := "key" + strconv.Itoa(i)
+		store.Add(key, key)
+	}
+
+	items, err := store.ByIndex("byValue", "key7")
+	assert.NoError(t, err)
+	assert.Equal(t, []any{"key7"}, items)
+
+	err = store.AddIndexers(Indexers{"byValue": func(obj any) ([]string, error) { return nil, nil }})
+	assert.Error(t, err)
+}
+
+func TestShardedThreadSafeStoreAddIndexersConcurrentWithOps(t *testing.T) {
+	store := NewShardedThreadSafeStore(Indexers{}, Indices{}, 8)
+
+	var wg sync.WaitGroup
+	wg.Add(3)
+
+	go func() {
+		defer wg.Done()
+		_ = store.AddIndexers(Indexers{
+			"byValue": func(obj any) ([]string, error) {
+				return []string{obj.(string)}, nil
+			},
+		})
+	}()
+
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 200; i++ {
+			key := "key" + strconv.Itoa(i)
+			store.Add(key, key)
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 200; i++ {
+			_ = store.GetIndexers()
+		}
+	}()
+
+	wg.Wait()
+	assert.Len(t, store.List(), 200)
+}
+
+func benchmarkShardedIndexer(b *testing.B, store ThreadSafeStore) {
+	objectCount := 5000
+	objects := make([]string, 0, objectCount)
+	for i := 0; i < objectCount; i++ {
+		objects = append(objects, fmt.Sprintf("object-number-%d", i))
+	}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			key := objects[i%objectCount]
+			store.Update(key, key)
+			i++
+		}
+	})
+}
+
+func BenchmarkIndexerNonSharded(b *testing.B) {
+	testIndexer := "testIndexer"
+	indexers := Indexers{
+		testIndexer: func(obj any) ([]string, error) {
+			return []string{obj.(string)}, nil
+		},
+	}
+	store := NewThreadSafeStore(indexers, Indices{})
+	benchmarkShardedIndexer(b, store)
+}
+
+func BenchmarkIndexerSharded(b *testing.B) {
+	testIndexer := "testIndexer"
+	indexers := Indexers{
+		testIndexer: func(obj any) ([]string, error) {
+			return []string{obj.(string)}, nil
+		},
+	}
+	store := NewShardedThreadSafeStore(indexers, Indices{}, 16)
+	benchmarkShardedIndexer(b, store)
+}
@@ -0,0 +1,154 @@
+package cache
+
+import (
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Encoder writes successive values to an underlying stream.
+type Encoder interface {
+	Encode(v any) error
+}
+
+// Decoder reads successive values from an underlying stream, in the same
+// order an Encoder wrote them.
+type Decoder interface {
+	Decode(v any) error
+}
+
+// Codec builds the Encoder/Decoder used to read and write a store
+// snapshot. A single Encoder/Decoder must be reused for the whole header
+// plus item stream: encoding/json's Decoder buffers ahead of the current
+// value, so recreating it per record would silently drop buffered data.
+// Implementations must round-trip the concrete type of every value passed
+// to Encode; the gob-based GobCodec requires such types to be registered
+// with gob.Register, same as any other use of encoding/gob with interface
+// values.
+type Codec interface {
+	NewEncoder(w io.Writer) Encoder
+	NewDecoder(r io.Reader) Decoder
+}
+
+type gobCodec struct{}
+
+func (gobCodec) NewEncoder(w io.Writer) Encoder { return gob.NewEncoder(w) }
+func (gobCodec) NewDecoder(r io.Reader) Decoder { return gob.NewDecoder(r) }
+
+// GobCodec is the default Codec used by NewThreadSafeStore.
+var GobCodec Codec = gobCodec{}
+
+type jsonCodec struct{}
+
+func (jsonCodec) NewEncoder(w io.Writer) Encoder { return json.NewEncoder(w) }
+func (jsonCodec) NewDecoder(r io.Reader) Decoder { return json.NewDecoder(r) }
+
+// JSONCodec is a human-readable alternative to GobCodec; unlike gob it
+// doesn't require registering concrete types, but Restore will hand back
+// json.Unmarshal's generic representation (map[string]any, float64, ...)
+// rather than the original concrete type.
+var JSONCodec Codec = jsonCodec{}
+
+// snapshotSchemaVersion is bumped whenever the on-disk record layout changes.
+const snapshotSchemaVersion = 1
+
+// snapshotHeader precedes the item records in a snapshot stream.
+type snapshotHeader struct {
+	SchemaVersion   int
+	ItemCount       int
+	IndexerNames    []string
+	ResourceVersion string
+}
+
+// snapshotRecord is one {key, object} pair within a snapshot stream.
+type snapshotRecord struct {
+	Key string
+	Obj any
+}
+
+func sortedIndexerNames(indexers Indexers) []string {
+	names := make([]string, 0, len(indexers))
+	for name := range indexers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func equalStringSlices(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Snapshot writes a header (schema version, item count, indexer names for
+// validation, resourceVersion) followed by one record per item, all under
+// the store's read lock, using c.codec.
+func (c *threadSafeMap) Snapshot(w io.Writer) error {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	header := snapshotHeader{
+		SchemaVersion:   snapshotSchemaVersion,
+		ItemCount:       len(c.items),
+		IndexerNames:    sortedIndexerNames(c.index.indexers),
+		ResourceVersion: c.resourceVersion,
+	}
+	enc := c.codec.NewEncoder(w)
+	if err := enc.Encode(header); err != nil {
+		return fmt.Errorf("encoding snapshot header: %w", err)
+	}
+
+	for key, obj := range c.items {
+		record := snapshotRecord{Key: key, Obj: obj}
+		if err := enc.Encode(record); err != nil {
+			return fmt.Errorf("encoding snapshot record for key %q: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// Restore reads a snapshot written by Snapshot and installs it via the
+// equivalent of Replace, rebuilding indices from scratch under the write
+// lock. It fails if the snapshot's indexer names don't match this store's,
+// since that means the records wouldn't be indexed the way callers expect.
+func (c *threadSafeMap) Restore(r io.Reader) error {
+	dec := c.codec.NewDecoder(r)
+	var header snapshotHeader
+	if err := dec.Decode(&header); err != nil {
+		return fmt.Errorf("decoding snapshot header: %w", err)
+	}
+	if header.SchemaVersion != snapshotSchemaVersion {
+		return fmt.Errorf("unsupported snapshot schema version %d", header.SchemaVersion)
+	}
+
+	c.lock.RLock()
+	indexerNames := sortedIndexerNames(c.index.indexers)
+	c.lock.RUnlock()
+	if !equalStringSlices(indexerNames, header.IndexerNames) {
+		return fmt.Errorf("snapshot indexers %v do not match store indexers %v", header.IndexerNames, indexerNames)
+	}
+
+	items := make(map[string]any, header.ItemCount)
+	for i := 0; i < header.ItemCount; i++ {
+		var record snapshotRecord
+		if err := dec.Decode(&record); err != nil {
+			return fmt.Errorf("decoding snapshot record %d: %w", i, err)
+		}
+		items[record.Key] = record.Obj
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.replaceLocked(items)
+	c.resourceVersion = header.ResourceVersion
+	return nil
+}
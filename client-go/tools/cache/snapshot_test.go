@@ -0,0 +1,90 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/gob"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	gob.Register("")
+}
+
+func TestSnapshotRestoreRoundTrip(t *testing.T) {
+	indexers := Indexers{
+		"byValue": func(obj any) ([]string, error) {
+			return []string{obj.(string)}, nil
+		},
+	}
+	store := NewThreadSafeStore(indexers, Indices{})
+	store.Add("a", "alpha")
+	store.Add("b", "bravo")
+	store.Replace(map[string]any{"a": "alpha", "b": "bravo"}, "42")
+
+	var buf bytes.Buffer
+	assert.NoError(t, store.Snapshot(&buf))
+
+	restored := NewThreadSafeStore(indexers, Indices{})
+	assert.NoError(t, restored.Restore(&buf))
+
+	assert.ElementsMatch(t, []string{"a", "b"}, restored.ListKeys())
+	items, err := restored.ByIndex("byValue", "alpha")
+	assert.NoError(t, err)
+	assert.Equal(t, []any{"alpha"}, items)
+}
+
+func TestRestoreRejectsIndexerMismatch(t *testing.T) {
+	store := NewThreadSafeStore(Indexers{
+		"byValue": func(obj any) ([]string, error) { return []string{obj.(string)}, nil },
+	}, Indices{})
+	store.Add("a", "alpha")
+
+	var buf bytes.Buffer
+	assert.NoError(t, store.Snapshot(&buf))
+
+	restored := NewThreadSafeStore(Indexers{}, Indices{})
+	err := restored.Restore(&buf)
+	assert.Error(t, err)
+}
+
+func TestSnapshotRestoreJSONCodec(t *testing.T) {
+	store := NewThreadSafeStoreWithCodec(Indexers{}, Indices{}, JSONCodec)
+	store.Add("a", "alpha")
+	store.Add("b", "bravo")
+
+	var buf bytes.Buffer
+	assert.NoError(t, store.Snapshot(&buf))
+
+	restored := NewThreadSafeStoreWithCodec(Indexers{}, Indices{}, JSONCodec)
+	assert.NoError(t, restored.Restore(&buf))
+
+	assert.ElementsMatch(t, []string{"a", "b"}, restored.ListKeys())
+}
+
+func TestShardedSnapshotRestoreRoundTrip(t *testing.T) {
+	store := NewShardedThreadSafeStore(Indexers{}, Indices{}, 4)
+	for i := 0; i < 20; i++ {
+		key := string(rune('a' + i))
+		store.Add(key, key)
+	}
+	store.Replace(copyItems(store), "7")
+
+	var buf bytes.Buffer
+	assert.NoError(t, store.Snapshot(&buf))
+
+	restored := NewShardedThreadSafeStore(Indexers{}, Indices{}, 4)
+	assert.NoError(t, restored.Restore(&buf))
+
+	assert.Len(t, restored.List(), 20)
+}
+
+func copyItems(store ThreadSafeStore) map[string]any {
+	items := map[string]any{}
+	for _, key := range store.ListKeys() {
+		obj, _ := store.Get(key)
+		items[key] = obj
+	}
+	return items
+}
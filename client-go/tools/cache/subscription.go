@@ -0,0 +1,273 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// EventType describes the kind of change that produced an Event.
+type EventType string
+
+const (
+	Added   EventType = "ADDED"
+	Updated EventType = "UPDATED"
+	Deleted EventType = "DELETED"
+)
+
+// Event describes a single Add/Update/Delete observed on a ThreadSafeStore.
+// OldObject is only populated for Updated events.
+type Event struct {
+	Type      EventType
+	Key       string
+	Object    any
+	OldObject any
+}
+
+// FilterFunc returns true if the given object should be kept.
+type FilterFunc func(obj any) bool
+
+// ResourceEventHandler can handle notifications for events that happen to a
+// resource. The events are informational only, so it is safe to use a
+// type check to decide how to handle a given notification.
+type ResourceEventHandler interface {
+	OnAdd(obj any)
+	OnUpdate(oldObj, newObj any)
+	OnDelete(obj any)
+}
+
+// ResourceEventHandlerFuncs is an adaptor to let you easily specify as many
+// or as few of the notification functions as you want while still
+// implementing ResourceEventHandler. Any nil function is a no-op.
+type ResourceEventHandlerFuncs struct {
+	AddFunc    func(obj any)
+	UpdateFunc func(oldObj, newObj any)
+	DeleteFunc func(obj any)
+}
+
+func (r ResourceEventHandlerFuncs) OnAdd(obj any) {
+	if r.AddFunc != nil {
+		r.AddFunc(obj)
+	}
+}
+
+func (r ResourceEventHandlerFuncs) OnUpdate(oldObj, newObj any) {
+	if r.UpdateFunc != nil {
+		r.UpdateFunc(oldObj, newObj)
+	}
+}
+
+func (r ResourceEventHandlerFuncs) OnDelete(obj any) {
+	if r.DeleteFunc != nil {
+		r.DeleteFunc(obj)
+	}
+}
+
+// Registration is returned by Subscribe and lets the caller stop receiving
+// events.
+type Registration interface {
+	Unregister() error
+}
+
+// defaultSubscriberBufferSize bounds how many undelivered events a single
+// subscriber may accumulate, beyond its initial synthetic Add batch, before
+// it is considered a slow consumer.
+const defaultSubscriberBufferSize = 1024
+
+// subscriber is a bounded ring buffer of pending events. push never blocks:
+// once the buffer is full the subscriber is evicted so that a stuck handler
+// cannot block the write path.
+type subscriber struct {
+	mu      sync.Mutex
+	buf     []Event
+	head    int
+	size    int
+	notify  chan struct{}
+	closed  bool
+	evicted bool
+}
+
+func newSubscriber(capacity int) *subscriber {
+	return &subscriber{
+		buf:    make([]Event, capacity),
+		notify: make(chan struct{}, 1),
+	}
+}
+
+func (s *subscriber) wake() {
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (s *subscriber) push(e Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed || s.evicted {
+		return
+	}
+	if s.size == len(s.buf) {
+		// Slow consumer: drop it rather than block the writer that called push.
+		s.evicted = true
+		s.closed = true
+		s.wake()
+		return
+	}
+	idx := (s.head + s.size) % len(s.buf)
+	s.buf[idx] = e
+	s.size++
+	s.wake()
+}
+
+func (s *subscriber) pop() (Event, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.size == 0 {
+		return Event{}, false
+	}
+	e := s.buf[s.head]
+	s.head = (s.head + 1) % len(s.buf)
+	s.size--
+	return e, true
+}
+
+func (s *subscriber) done() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed && s.size == 0
+}
+
+func (s *subscriber) stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	s.wake()
+}
+
+type registration struct {
+	id    int64
+	store *threadSafeMap
+}
+
+func (r *registration) Unregister() error {
+	r.store.unregisterSubscriber(r.id)
+	return nil
+}
+
+// registerSubscriber registers sub and seeds its buffer with a synthetic Add
+// for every item currently in the store, all under a single write lock
+// acquisition. This atomicity is what lets Subscribe/Watch promise an
+// initial "list" batch followed only by live deltas: if registration and
+// the initial snapshot were two separate critical sections, a concurrent
+// Update/Delete landing in the gap between them could reach the subscriber
+// before the synthetic Add for that key did.
+func (c *threadSafeMap) registerSubscriber() (*subscriber, int64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.subscribers == nil {
+		c.subscribers = map[int64]*subscriber{}
+	}
+	id := c.nextSubscriberID
+	c.nextSubscriberID++
+	// Leave room for the whole initial batch on top of the live-delta bound,
+	// so a large store does not evict the subscriber before it starts.
+	sub := newSubscriber(defaultSubscriberBufferSize + len(c.items))
+	c.subscribers[id] = sub
+
+	for key, obj := range c.items {
+		sub.push(Event{Type: Added, Key: key, Object: obj})
+	}
+
+	return sub, id
+}
+
+func (c *threadSafeMap) unregisterSubscriber(id int64) {
+	c.lock.Lock()
+	sub, ok := c.subscribers[id]
+	if ok {
+		delete(c.subscribers, id)
+	}
+	c.lock.Unlock()
+	if ok {
+		sub.stop()
+	}
+}
+
+// notifySubscribers fans an event out to every live subscriber. Callers
+// must hold c.lock (write-locked) since it is invoked from Update, Delete
+// and Replace.
+func (c *threadSafeMap) notifySubscribers(e Event) {
+	for _, sub := range c.subscribers {
+		sub.push(e)
+	}
+}
+
+// Subscribe registers handler to be called for Add/Update/Delete events on
+// the store. It first delivers a synthetic Add for every existing item,
+// then streams live deltas until the returned Registration is unregistered.
+func (c *threadSafeMap) Subscribe(handler ResourceEventHandler) (Registration, error) {
+	if handler == nil {
+		return nil, fmt.Errorf("nil ResourceEventHandler")
+	}
+
+	sub, id := c.registerSubscriber()
+	go func() {
+		for {
+			e, ok := sub.pop()
+			if ok {
+				switch e.Type {
+				case Added:
+					handler.OnAdd(e.Object)
+				case Updated:
+					handler.OnUpdate(e.OldObject, e.Object)
+				case Deleted:
+					handler.OnDelete(e.Object)
+				}
+				continue
+			}
+			if sub.done() {
+				return
+			}
+			<-sub.notify
+		}
+	}()
+
+	return &registration{id: id, store: c}, nil
+}
+
+// Watch returns a channel of Events matching filter, starting with a
+// synthetic Add for every item already in the store. The channel is closed
+// when ctx is done or when the subscriber is evicted as a slow consumer.
+func (c *threadSafeMap) Watch(ctx context.Context, filter FilterFunc) (<-chan Event, error) {
+	sub, id := c.registerSubscriber()
+	out := make(chan Event, defaultSubscriberBufferSize)
+
+	go func() {
+		defer close(out)
+		defer c.unregisterSubscriber(id)
+		for {
+			e, ok := sub.pop()
+			if ok {
+				if filter == nil || filter(e.Object) {
+					select {
+					case out <- e:
+					case <-ctx.Done():
+						return
+					}
+				}
+				continue
+			}
+			if sub.done() {
+				return
+			}
+			select {
+			case <-sub.notify:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
@@ -0,0 +1,160 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingHandler struct {
+	mu      sync.Mutex
+	added   []any
+	updated []any
+	deleted []any
+}
+
+func (r *recordingHandler) OnAdd(obj any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.added = append(r.added, obj)
+}
+
+func (r *recordingHandler) OnUpdate(oldObj, newObj any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.updated = append(r.updated, newObj)
+}
+
+func (r *recordingHandler) OnDelete(obj any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deleted = append(r.deleted, obj)
+}
+
+func (r *recordingHandler) addedCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.added)
+}
+
+func (r *recordingHandler) updatedCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.updated)
+}
+
+func (r *recordingHandler) deletedCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.deleted)
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("condition not met within deadline")
+}
+
+func TestSubscribeReceivesInitialAddBatch(t *testing.T) {
+	store := NewThreadSafeStore(Indexers{}, Indices{}).(*threadSafeMap)
+	store.Add("a", "alpha")
+	store.Add("b", "bravo")
+
+	handler := &recordingHandler{}
+	reg, err := store.Subscribe(handler)
+	assert.NoError(t, err)
+	defer reg.Unregister()
+
+	waitFor(t, func() bool { return handler.addedCount() == 2 })
+}
+
+func TestSubscribeReceivesLiveDeltas(t *testing.T) {
+	store := NewThreadSafeStore(Indexers{}, Indices{}).(*threadSafeMap)
+	handler := &recordingHandler{}
+	reg, err := store.Subscribe(handler)
+	assert.NoError(t, err)
+	defer reg.Unregister()
+
+	store.Add("a", "alpha")
+	waitFor(t, func() bool { return handler.addedCount() == 1 })
+
+	store.Update("a", "alpha2")
+	waitFor(t, func() bool { return handler.updatedCount() == 1 })
+
+	store.Delete("a")
+	waitFor(t, func() bool { return handler.deletedCount() == 1 })
+}
+
+func TestSubscribeInitialAddPrecedesConcurrentUpdate(t *testing.T) {
+	store := NewThreadSafeStore(Indexers{}, Indices{}).(*threadSafeMap)
+	store.Add("a", "alpha")
+
+	handler := &recordingHandler{}
+	reg, err := store.Subscribe(handler)
+	assert.NoError(t, err)
+	defer reg.Unregister()
+
+	store.Update("a", "alpha2")
+	waitFor(t, func() bool { return handler.updatedCount() == 1 })
+
+	handler.mu.Lock()
+	defer handler.mu.Unlock()
+	assert.Equal(t, []any{"alpha"}, handler.added)
+	assert.Equal(t, []any{"alpha2"}, handler.updated)
+}
+
+func TestSubscribeUnregisterStopsDelivery(t *testing.T) {
+	store := NewThreadSafeStore(Indexers{}, Indices{}).(*threadSafeMap)
+	handler := &recordingHandler{}
+	reg, err := store.Subscribe(handler)
+	assert.NoError(t, err)
+
+	assert.NoError(t, reg.Unregister())
+	store.Add("a", "alpha")
+
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, 0, handler.addedCount())
+}
+
+func TestWatchFiltersEvents(t *testing.T) {
+	store := NewThreadSafeStore(Indexers{}, Indices{}).(*threadSafeMap)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, err := store.Watch(ctx, func(obj any) bool {
+		return obj.(string) != "skip-me"
+	})
+	assert.NoError(t, err)
+
+	store.Add("a", "skip-me")
+	store.Add("b", "keep-me")
+
+	select {
+	case e := <-ch:
+		assert.Equal(t, "keep-me", e.Object)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for watch event")
+	}
+}
+
+func TestSubscriberEvictedWhenBufferFull(t *testing.T) {
+	store := NewThreadSafeStore(Indexers{}, Indices{}).(*threadSafeMap)
+	sub, id := store.registerSubscriber()
+	defer store.unregisterSubscriber(id)
+
+	for i := 0; i < defaultSubscriberBufferSize+10; i++ {
+		sub.push(Event{Type: Added, Key: "k", Object: i})
+	}
+
+	assert.True(t, sub.evicted)
+}
@@ -1,7 +1,9 @@
 package cache
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"sync"
 
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -23,6 +25,34 @@ type ThreadSafeStore interface {
 
 	AddIndexers(newIndexers Indexers) error
 	Resync() error
+
+	// AddOrderedIndexers registers indexers that maintain their keys in
+	// sorted order, enabling RangeByIndex/AscendByIndex/DescendByIndex.
+	AddOrderedIndexers(newIndexers map[string]OrderedIndexFunc) error
+	// RangeByIndex returns every object whose ordered indexed value falls
+	// within [lo, hi], in ascending order.
+	RangeByIndex(indexName, lo, hi string) ([]any, error)
+	// AscendByIndex walks the ordered index in ascending order starting at
+	// pivot, calling fn for each (key, object) pair until fn returns false.
+	AscendByIndex(indexName, pivot string, fn func(key string, obj any) bool) error
+	// DescendByIndex walks the ordered index in descending order starting
+	// at pivot, calling fn for each (key, object) pair until fn returns false.
+	DescendByIndex(indexName, pivot string, fn func(key string, obj any) bool) error
+
+	// Subscribe registers handler to receive Add/Update/Delete events from
+	// the store, starting with a synthetic Add batch for current contents.
+	Subscribe(handler ResourceEventHandler) (Registration, error)
+	// Watch behaves like Subscribe but delivers matching events on a
+	// channel instead of calling a handler.
+	Watch(ctx context.Context, filter FilterFunc) (<-chan Event, error)
+
+	// Snapshot streams every item, plus the resourceVersion from the most
+	// recent Replace, to w using the store's Codec.
+	Snapshot(w io.Writer) error
+	// Restore replaces the store's contents with a snapshot previously
+	// written by Snapshot, failing if the snapshot's indexer names don't
+	// match this store's.
+	Restore(r io.Reader) error
 }
 
 type storeIndex struct {
@@ -30,10 +60,15 @@ type storeIndex struct {
 	indexers Indexers
 	// indices maps a name to an Index
 	indices Indices
+	// orderedIndexers maps a name to an OrderedIndexFunc
+	orderedIndexers map[string]OrderedIndexFunc
+	// orderedIndices maps a name to the B-tree backing that ordered index
+	orderedIndices map[string]*orderedIndex
 }
 
 func (i *storeIndex) reset() {
 	i.indices = Indices{}
+	i.resetOrdered()
 }
 
 func (i *storeIndex) getKeysFromIndex(indexName string, obj any) (sets.String, error) {
@@ -168,6 +203,17 @@ type threadSafeMap struct {
 
 	// index implements the indexing functionality
 	index *storeIndex
+
+	// subscribers holds every live Subscribe/Watch registration, keyed by
+	// the id handed out by registerSubscriber.
+	subscribers      map[int64]*subscriber
+	nextSubscriberID int64
+
+	// resourceVersion is the resourceVersion passed to the most recent
+	// Replace call, persisted by Snapshot so Restore can recover it.
+	resourceVersion string
+	// codec encodes/decodes Snapshot/Restore records.
+	codec Codec
 }
 
 func (c *threadSafeMap) Add(key string, obj any) {
@@ -177,9 +223,16 @@ func (c *threadSafeMap) Add(key string, obj any) {
 func (c *threadSafeMap) Update(key string, obj any) {
 	c.lock.Lock()
 	defer c.lock.Unlock()
-	oldObject := c.items[key]
+	oldObject, exists := c.items[key]
 	c.items[key] = obj
 	c.index.updateIndices(oldObject, obj, key)
+	c.index.updateOrderedIndices(oldObject, obj, key)
+
+	if exists {
+		c.notifySubscribers(Event{Type: Updated, Key: key, Object: obj, OldObject: oldObject})
+	} else {
+		c.notifySubscribers(Event{Type: Added, Key: key, Object: obj})
+	}
 }
 
 func (c *threadSafeMap) Delete(key string) {
@@ -187,7 +240,9 @@ func (c *threadSafeMap) Delete(key string) {
 	defer c.lock.Unlock()
 	if obj, exists := c.items[key]; exists {
 		c.index.updateIndices(obj, nil, key)
+		c.index.updateOrderedIndices(obj, nil, key)
 		delete(c.items, key)
+		c.notifySubscribers(Event{Type: Deleted, Key: key, Object: obj})
 	}
 }
 
@@ -221,11 +276,37 @@ func (c *threadSafeMap) ListKeys() []string {
 func (c *threadSafeMap) Replace(items map[string]any, resourceVersion string) {
 	c.lock.Lock()
 	defer c.lock.Unlock()
+	c.replaceLocked(items)
+	c.resourceVersion = resourceVersion
+}
+
+// replaceLocked does the work of Replace assuming c.lock is already held for
+// writing. It is split out so that a sharded store can replace every shard
+// under every shard's lock without re-entering c.lock.Lock.
+func (c *threadSafeMap) replaceLocked(items map[string]any) {
+	oldItems := c.items
 	c.items = items
 
 	c.index.reset()
 	for key, item := range c.items {
 		c.index.updateIndices(nil, item, key)
+		c.index.updateOrderedIndices(nil, item, key)
+	}
+
+	if len(c.subscribers) == 0 {
+		return
+	}
+	for key, newObj := range items {
+		if oldObj, existed := oldItems[key]; existed {
+			c.notifySubscribers(Event{Type: Updated, Key: key, Object: newObj, OldObject: oldObj})
+		} else {
+			c.notifySubscribers(Event{Type: Added, Key: key, Object: newObj})
+		}
+	}
+	for key, oldObj := range oldItems {
+		if _, stillPresent := items[key]; !stillPresent {
+			c.notifySubscribers(Event{Type: Deleted, Key: key, Object: oldObj})
+		}
 	}
 }
 
@@ -300,11 +381,19 @@ func (c *threadSafeMap) Resync() error {
 }
 
 func NewThreadSafeStore(indexers Indexers, indices Indices) ThreadSafeStore {
+	return NewThreadSafeStoreWithCodec(indexers, indices, GobCodec)
+}
+
+// NewThreadSafeStoreWithCodec is like NewThreadSafeStore but lets the
+// caller choose the Codec used by Snapshot/Restore instead of the default
+// GobCodec.
+func NewThreadSafeStoreWithCodec(indexers Indexers, indices Indices, codec Codec) ThreadSafeStore {
 	return &threadSafeMap{
 		items: map[string]any{},
 		index: &storeIndex{
 			indexers: indexers,
 			indices:  indices,
 		},
+		codec: codec,
 	}
 }
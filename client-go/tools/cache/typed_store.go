@@ -0,0 +1,82 @@
+package cache
+
+// TypedIndexFunc knows how to compute the set of indexed values for an
+// object of a concrete type T. It is the generic counterpart of IndexFunc.
+type TypedIndexFunc[T any] func(obj T) ([]string, error)
+
+// TypedStore is a generics-based wrapper around ThreadSafeStore that keeps
+// callers from having to type-assert every item out of an `any`-typed
+// store. It adapts to the existing Indexers/ThreadSafeStore internally, so
+// the untyped API remains available for callers that still need it.
+type TypedStore[T any] interface {
+	Add(key string, obj T)
+	Update(key string, obj T)
+	Delete(key string)
+	Get(key string) (item T, exists bool)
+	List() []T
+	ListKeys() []string
+	ByIndex(indexName, indexedValue string) ([]T, error)
+}
+
+type typedStore[T any] struct {
+	store ThreadSafeStore
+}
+
+// NewTypedThreadSafeStore builds a TypedStore[T] on top of a plain
+// ThreadSafeStore, adapting each TypedIndexFunc[T] into an IndexFunc.
+func NewTypedThreadSafeStore[T any](indexers map[string]TypedIndexFunc[T]) TypedStore[T] {
+	untyped := Indexers{}
+	for name, typedFunc := range indexers {
+		typedFunc := typedFunc
+		untyped[name] = func(obj any) ([]string, error) {
+			return typedFunc(obj.(T))
+		}
+	}
+	return &typedStore[T]{store: NewThreadSafeStore(untyped, Indices{})}
+}
+
+func (t *typedStore[T]) Add(key string, obj T) {
+	t.store.Add(key, obj)
+}
+
+func (t *typedStore[T]) Update(key string, obj T) {
+	t.store.Update(key, obj)
+}
+
+func (t *typedStore[T]) Delete(key string) {
+	t.store.Delete(key)
+}
+
+func (t *typedStore[T]) Get(key string) (item T, exists bool) {
+	obj, exists := t.store.Get(key)
+	if !exists {
+		var zero T
+		return zero, false
+	}
+	return obj.(T), true
+}
+
+func (t *typedStore[T]) List() []T {
+	items := t.store.List()
+	out := make([]T, 0, len(items))
+	for _, item := range items {
+		out = append(out, item.(T))
+	}
+	return out
+}
+
+func (t *typedStore[T]) ListKeys() []string {
+	return t.store.ListKeys()
+}
+
+func (t *typedStore[T]) ByIndex(indexName, indexedValue string) ([]T, error) {
+	items, err := t.store.ByIndex(indexName, indexedValue)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]T, 0, len(items))
+	for _, item := range items {
+		out = append(out, item.(T))
+	}
+	return out, nil
+}
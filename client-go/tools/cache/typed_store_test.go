@@ -0,0 +1,60 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testPod struct {
+	name     string
+	nodeName string
+}
+
+func TestTypedStoreAddGetList(t *testing.T) {
+	store := NewTypedThreadSafeStore[testPod](map[string]TypedIndexFunc[testPod]{
+		"byNode": func(obj testPod) ([]string, error) {
+			return []string{obj.nodeName}, nil
+		},
+	})
+
+	store.Add("pod1", testPod{name: "pod1", nodeName: "node-a"})
+	store.Add("pod2", testPod{name: "pod2", nodeName: "node-b"})
+
+	pod, exists := store.Get("pod1")
+	assert.True(t, exists)
+	assert.Equal(t, "pod1", pod.name)
+
+	_, exists = store.Get("missing")
+	assert.False(t, exists)
+
+	assert.ElementsMatch(t, []string{"pod1", "pod2"}, store.ListKeys())
+	assert.Len(t, store.List(), 2)
+}
+
+func TestTypedStoreByIndex(t *testing.T) {
+	store := NewTypedThreadSafeStore[testPod](map[string]TypedIndexFunc[testPod]{
+		"byNode": func(obj testPod) ([]string, error) {
+			return []string{obj.nodeName}, nil
+		},
+	})
+
+	store.Add("pod1", testPod{name: "pod1", nodeName: "node-a"})
+	store.Add("pod2", testPod{name: "pod2", nodeName: "node-a"})
+	store.Add("pod3", testPod{name: "pod3", nodeName: "node-b"})
+
+	pods, err := store.ByIndex("byNode", "node-a")
+	assert.NoError(t, err)
+	assert.Len(t, pods, 2)
+
+	store.Update("pod1", testPod{name: "pod1", nodeName: "node-b"})
+	pods, err = store.ByIndex("byNode", "node-a")
+	assert.NoError(t, err)
+	assert.Len(t, pods, 1)
+	assert.Equal(t, "pod2", pods[0].name)
+
+	store.Delete("pod2")
+	pods, err = store.ByIndex("byNode", "node-a")
+	assert.NoError(t, err)
+	assert.Len(t, pods, 0)
+}
@@ -0,0 +1,197 @@
+package workqueue
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+)
+
+// DelayingInterface extends Interface with the ability to schedule an item
+// to be added only once some duration has elapsed.
+type DelayingInterface interface {
+	Interface
+	// AddAfter adds item after duration has elapsed. If item is already
+	// waiting to be added with an earlier ready time, that earlier time
+	// wins and this call is a no-op.
+	AddAfter(item any, duration time.Duration)
+}
+
+// maxWait bounds how long the waiting loop ever sleeps without being
+// woken, so a timer that was somehow missed can't delay an item forever.
+const maxWait = 10 * time.Second
+
+// NewDelayingQueue constructs a new DelayingInterface backed by a plain
+// Type, with a single goroutine managing the delayed entries.
+func NewDelayingQueue() DelayingInterface {
+	q := &delayingType{
+		Interface:       newQueue(),
+		heartbeat:       time.NewTicker(maxWait),
+		stopCh:          make(chan struct{}),
+		waitingForAddCh: make(chan *waitFor, 1000),
+	}
+	go q.waitingLoop()
+	return q
+}
+
+type delayingType struct {
+	Interface
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+
+	// heartbeat ensures the waiting loop wakes up periodically even if it
+	// somehow missed a timer, so it can re-check the heap.
+	heartbeat *time.Ticker
+
+	// waitingForAddCh carries newly delayed entries into the waiting
+	// loop's goroutine; it is the only thing that touches the heap.
+	waitingForAddCh chan *waitFor
+}
+
+// waitFor is a single item waiting to become ready.
+type waitFor struct {
+	data    any
+	readyAt time.Time
+	// index is maintained by container/heap.
+	index int
+}
+
+// waitForPriorityQueue implements a min-heap of waitFor entries ordered by
+// readyAt, with Peek for inspecting the earliest entry without popping it.
+type waitForPriorityQueue []*waitFor
+
+func (pq waitForPriorityQueue) Len() int { return len(pq) }
+func (pq waitForPriorityQueue) Less(i, j int) bool {
+	return pq[i].readyAt.Before(pq[j].readyAt)
+}
+func (pq waitForPriorityQueue) Swap(i, j int) {
+	pq[i], pq[j] = pq[j], pq[i]
+	pq[i].index = i
+	pq[j].index = j
+}
+
+func (pq *waitForPriorityQueue) Push(x any) {
+	item := x.(*waitFor)
+	item.index = len(*pq)
+	*pq = append(*pq, item)
+}
+
+func (pq *waitForPriorityQueue) Pop() any {
+	old := *pq
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	item.index = -1
+	*pq = old[:n-1]
+	return item
+}
+
+func (pq waitForPriorityQueue) Peek() *waitFor {
+	return pq[0]
+}
+
+func (q *delayingType) ShutDown() {
+	q.stopOnce.Do(func() {
+		q.Interface.ShutDown()
+		close(q.stopCh)
+		q.heartbeat.Stop()
+	})
+}
+
+// AddAfter schedules item to be added once duration has elapsed. A
+// duration of zero or less adds it immediately.
+func (q *delayingType) AddAfter(item any, duration time.Duration) {
+	if q.Interface.ShuttingDown() {
+		return
+	}
+	if duration <= 0 {
+		q.Add(item)
+		return
+	}
+
+	select {
+	case <-q.stopCh:
+	case q.waitingForAddCh <- &waitFor{data: item, readyAt: time.Now().Add(duration)}:
+	}
+}
+
+// waitingLoop is the single goroutine that owns the delay heap: it sleeps
+// until the earliest entry is ready, promotes ready entries to the base
+// queue, and folds in new AddAfter calls, coalescing duplicates so that an
+// item already waiting keeps its earliest requested ready time.
+func (q *delayingType) waitingLoop() {
+	never := make(<-chan time.Time)
+
+	waitingForQueue := &waitForPriorityQueue{}
+	heap.Init(waitingForQueue)
+
+	waitingEntryByData := map[any]*waitFor{}
+
+	for {
+		if q.Interface.ShuttingDown() {
+			return
+		}
+
+		now := time.Now()
+
+		for waitingForQueue.Len() > 0 {
+			entry := waitingForQueue.Peek()
+			if entry.readyAt.After(now) {
+				break
+			}
+
+			entry = heap.Pop(waitingForQueue).(*waitFor)
+			q.Add(entry.data)
+			delete(waitingEntryByData, entry.data)
+		}
+
+		nextReadyAt := never
+		if waitingForQueue.Len() > 0 {
+			nextReadyAt = time.After(waitingForQueue.Peek().readyAt.Sub(now))
+		}
+
+		select {
+		case <-q.stopCh:
+			return
+
+		case <-q.heartbeat.C:
+			// loop around and re-check the heap
+
+		case <-nextReadyAt:
+			// loop around and promote whatever is now ready
+
+		case entry := <-q.waitingForAddCh:
+			q.insert(waitingForQueue, waitingEntryByData, entry)
+
+			// Drain any other entries that arrived in the meantime so we
+			// don't go back to sleep with pending work already queued up.
+			drained := false
+			for !drained {
+				select {
+				case entry := <-q.waitingForAddCh:
+					q.insert(waitingForQueue, waitingEntryByData, entry)
+				default:
+					drained = true
+				}
+			}
+		}
+	}
+}
+
+func (q *delayingType) insert(pq *waitForPriorityQueue, knownEntries map[any]*waitFor, entry *waitFor) {
+	if existing, exists := knownEntries[entry.data]; exists {
+		if existing.readyAt.After(entry.readyAt) {
+			existing.readyAt = entry.readyAt
+			heap.Fix(pq, existing.index)
+		}
+		return
+	}
+
+	if !entry.readyAt.After(time.Now()) {
+		q.Add(entry.data)
+		return
+	}
+
+	heap.Push(pq, entry)
+	knownEntries[entry.data] = entry
+}
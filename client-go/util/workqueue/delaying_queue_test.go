@@ -0,0 +1,77 @@
+package workqueue_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/GRTheory/k8s-learn/client-go/util/workqueue"
+)
+
+func TestDelayingQueueAddAfterOrdering(t *testing.T) {
+	q := workqueue.NewDelayingQueue()
+	defer q.ShutDown()
+
+	q.AddAfter("slow", 60*time.Millisecond)
+	q.AddAfter("fast", 10*time.Millisecond)
+
+	first, shutdown := q.Get()
+	if shutdown {
+		t.Fatal("unexpected shutdown")
+	}
+	if first != "fast" {
+		t.Errorf("expected \"fast\" to be ready first, got %v", first)
+	}
+	q.Done(first)
+
+	second, shutdown := q.Get()
+	if shutdown {
+		t.Fatal("unexpected shutdown")
+	}
+	if second != "slow" {
+		t.Errorf("expected \"slow\" second, got %v", second)
+	}
+	q.Done(second)
+}
+
+func TestDelayingQueueCoalescesDuplicates(t *testing.T) {
+	q := workqueue.NewDelayingQueue()
+	defer q.ShutDown()
+
+	q.AddAfter("item", 200*time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	q.AddAfter("item", 10*time.Millisecond)
+
+	start := time.Now()
+	item, shutdown := q.Get()
+	elapsed := time.Since(start)
+	if shutdown {
+		t.Fatal("unexpected shutdown")
+	}
+	if item != "item" {
+		t.Fatalf("expected \"item\", got %v", item)
+	}
+	if elapsed > 150*time.Millisecond {
+		t.Errorf("expected the earlier ready time to win, took %v", elapsed)
+	}
+}
+
+func TestDelayingQueueShutDownWithPendingDelayedItems(t *testing.T) {
+	q := workqueue.NewDelayingQueue()
+	q.AddAfter("never-arrives", time.Hour)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		q.ShutDown()
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("ShutDown did not return promptly with a pending delayed item")
+	}
+
+	if _, shutdown := q.Get(); !shutdown {
+		t.Error("expected Get to report shutdown")
+	}
+}
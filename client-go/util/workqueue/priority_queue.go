@@ -0,0 +1,148 @@
+package workqueue
+
+import (
+	"container/heap"
+	"sync"
+)
+
+// PriorityInterface extends Interface with AddIfNotPresent, which (unlike
+// Add) leaves an item alone if it is currently being processed instead of
+// marking it dirty for re-processing.
+type PriorityInterface interface {
+	Interface
+	AddIfNotPresent(item any)
+}
+
+// NewPriorityQueue constructs a PriorityInterface that hands out items in
+// the order defined by less, rather than FIFO order.
+func NewPriorityQueue(less func(a, b any) bool) PriorityInterface {
+	return &priorityQueueType{
+		heap:  priorityHeap{less: less},
+		dirty: set{},
+		shutdownGroup: &shutdownGroup{
+			processing: set{},
+			cond:       sync.NewCond(&sync.Mutex{}),
+		},
+	}
+}
+
+type priorityItem struct {
+	value any
+	index int
+}
+
+// priorityHeap is a container/heap.Interface over pending items, ordered by
+// the caller-supplied less function.
+type priorityHeap struct {
+	items []*priorityItem
+	less  func(a, b any) bool
+}
+
+func (h priorityHeap) Len() int { return len(h.items) }
+func (h priorityHeap) Less(i, j int) bool {
+	return h.less(h.items[i].value, h.items[j].value)
+}
+func (h priorityHeap) Swap(i, j int) {
+	h.items[i], h.items[j] = h.items[j], h.items[i]
+	h.items[i].index = i
+	h.items[j].index = j
+}
+
+func (h *priorityHeap) Push(x any) {
+	item := x.(*priorityItem)
+	item.index = len(h.items)
+	h.items = append(h.items, item)
+}
+
+func (h *priorityHeap) Pop() any {
+	old := h.items
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	item.index = -1
+	h.items = old[:n-1]
+	return item
+}
+
+// priorityQueueType mirrors Type's dirty/processing bookkeeping, but hands
+// out items via a heap instead of a FIFO slice.
+type priorityQueueType struct {
+	*shutdownGroup
+
+	heap priorityHeap
+
+	dirty set
+}
+
+func (q *priorityQueueType) Add(item any) {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	if q.shuttingDown {
+		return
+	}
+	if q.dirty.has(item) {
+		return
+	}
+
+	q.dirty.insert(item)
+	if q.processing.has(item) {
+		return
+	}
+
+	heap.Push(&q.heap, &priorityItem{value: item})
+	q.cond.Signal()
+}
+
+// AddIfNotPresent adds item only if it is neither queued nor currently
+// being processed, so it never interrupts in-flight processing.
+func (q *priorityQueueType) AddIfNotPresent(item any) {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	if q.shuttingDown {
+		return
+	}
+	if q.dirty.has(item) || q.processing.has(item) {
+		return
+	}
+
+	q.dirty.insert(item)
+	heap.Push(&q.heap, &priorityItem{value: item})
+	q.cond.Signal()
+}
+
+func (q *priorityQueueType) Len() int {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	return q.heap.Len()
+}
+
+func (q *priorityQueueType) Get() (item any, shutdown bool) {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	for q.heap.Len() == 0 && !q.shuttingDown {
+		q.cond.Wait()
+	}
+	if q.heap.Len() == 0 {
+		return nil, true
+	}
+
+	pi := heap.Pop(&q.heap).(*priorityItem)
+	item = pi.value
+	q.processing.insert(item)
+	q.dirty.delete(item)
+
+	return item, false
+}
+
+func (q *priorityQueueType) Done(item any) {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	q.processing.delete(item)
+	if q.dirty.has(item) {
+		heap.Push(&q.heap, &priorityItem{value: item})
+		q.cond.Signal()
+	} else if q.processing.len() == 0 {
+		q.cond.Signal()
+	}
+}
+
@@ -0,0 +1,68 @@
+package workqueue_test
+
+import (
+	"testing"
+
+	"github.com/GRTheory/k8s-learn/client-go/util/workqueue"
+)
+
+func TestPriorityQueueOrdering(t *testing.T) {
+	q := workqueue.NewPriorityQueue(func(a, b any) bool {
+		return a.(int) < b.(int)
+	})
+	defer q.ShutDown()
+
+	for _, v := range []int{5, 1, 4, 2, 3} {
+		q.Add(v)
+	}
+
+	var got []int
+	for i := 0; i < 5; i++ {
+		item, shutdown := q.Get()
+		if shutdown {
+			t.Fatal("unexpected shutdown")
+		}
+		got = append(got, item.(int))
+		q.Done(item)
+	}
+
+	want := []int{1, 2, 3, 4, 5}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("item %d: got %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestPriorityQueueAddIfNotPresentSkipsInFlightItems(t *testing.T) {
+	q := workqueue.NewPriorityQueue(func(a, b any) bool {
+		return a.(string) < b.(string)
+	})
+	defer q.ShutDown()
+
+	q.Add("a")
+	item, _ := q.Get()
+
+	q.AddIfNotPresent(item)
+	if q.Len() != 0 {
+		t.Errorf("expected AddIfNotPresent to skip an in-flight item, queue len = %d", q.Len())
+	}
+
+	q.Done(item)
+	if q.Len() != 0 {
+		t.Errorf("expected queue to stay empty after Done, got len = %d", q.Len())
+	}
+}
+
+func TestPriorityQueueAddDeduplicates(t *testing.T) {
+	q := workqueue.NewPriorityQueue(func(a, b any) bool {
+		return a.(string) < b.(string)
+	})
+	defer q.ShutDown()
+
+	q.Add("a")
+	q.Add("a")
+	if q.Len() != 1 {
+		t.Errorf("expected duplicate Add to be deduplicated, got len = %d", q.Len())
+	}
+}
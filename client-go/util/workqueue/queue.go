@@ -0,0 +1,208 @@
+package workqueue
+
+import (
+	"sync"
+)
+
+// Interface is a FIFO work queue with set semantics: adding an item that is
+// already queued (and not yet Done) is a no-op, and adding an item while it
+// is being processed defers the re-add until Done is called for it.
+type Interface interface {
+	Add(item any)
+	Len() int
+	Get() (item any, shutdown bool)
+	Done(item any)
+	ShutDown()
+	ShutDownWithDrain()
+	ShuttingDown() bool
+}
+
+// New constructs a new empty Type.
+func New() *Type {
+	return newQueue()
+}
+
+func newQueue() *Type {
+	return &Type{
+		dirty: set{},
+		shutdownGroup: &shutdownGroup{
+			processing: set{},
+			cond:       sync.NewCond(&sync.Mutex{}),
+		},
+	}
+}
+
+// Type is the default, non-prioritized, non-delaying Interface.
+type Type struct {
+	*shutdownGroup
+
+	// queue holds the items in the order they should be processed. Every
+	// item in queue is also in dirty, and not in processing.
+	queue []any
+
+	// dirty holds every item that needs processing, whether or not it's
+	// currently being processed.
+	dirty set
+}
+
+// shutdownGroup holds the cond/drain bookkeeping shared by every Interface
+// implementation in this package (Type, priorityQueueType): the cond
+// guarding mutation, which items are currently being processed, and the
+// shutdown/drain flags. Implementations that need a different underlying
+// queue (e.g. a heap instead of a slice) still share this via embedding so
+// ShutDown/ShutDownWithDrain/ShuttingDown aren't reimplemented per queue.
+type shutdownGroup struct {
+	// processing holds the items currently being processed by Get, so a
+	// second Add for the same item can be deferred until Done.
+	processing set
+
+	cond *sync.Cond
+
+	shuttingDown bool
+	drain        bool
+}
+
+// ShutDown makes Get return shutdown=true once the queue is drained.
+func (q *shutdownGroup) ShutDown() {
+	q.setDrain(false)
+	q.shutdown()
+}
+
+// ShutDownWithDrain behaves like ShutDown but blocks until every item
+// already being processed (i.e. Get-ed but not yet Done-ed) has completed.
+func (q *shutdownGroup) ShutDownWithDrain() {
+	q.setDrain(true)
+	q.shutdown()
+	for q.isProcessing() && q.shouldDrain() {
+		q.waitForProcessing()
+	}
+}
+
+func (q *shutdownGroup) shutdown() {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	q.shuttingDown = true
+	q.cond.Broadcast()
+}
+
+// ShuttingDown reports whether ShutDown or ShutDownWithDrain was called.
+func (q *shutdownGroup) ShuttingDown() bool {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	return q.shuttingDown
+}
+
+func (q *shutdownGroup) setDrain(shouldDrain bool) {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	q.drain = shouldDrain
+}
+
+func (q *shutdownGroup) shouldDrain() bool {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	return q.drain
+}
+
+func (q *shutdownGroup) isProcessing() bool {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	return q.processing.len() != 0
+}
+
+// waitForProcessing waits for any processing item to call Done.
+func (q *shutdownGroup) waitForProcessing() {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	// Ensure that we do not wait on a queue which is already empty, as that
+	// would block indefinitely.
+	if q.processing.len() == 0 {
+		return
+	}
+	q.cond.Wait()
+}
+
+type empty struct{}
+type set map[any]empty
+
+func (s set) has(item any) bool {
+	_, exists := s[item]
+	return exists
+}
+
+func (s set) insert(item any) {
+	s[item] = empty{}
+}
+
+func (s set) delete(item any) {
+	delete(s, item)
+}
+
+func (s set) len() int {
+	return len(s)
+}
+
+// Add marks item as needing processing.
+func (q *Type) Add(item any) {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	if q.shuttingDown {
+		return
+	}
+	if q.dirty.has(item) {
+		return
+	}
+
+	q.dirty.insert(item)
+	if q.processing.has(item) {
+		return
+	}
+
+	q.queue = append(q.queue, item)
+	q.cond.Signal()
+}
+
+// Len returns the number of items waiting to be processed.
+func (q *Type) Len() int {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	return len(q.queue)
+}
+
+// Get blocks until it can return an item to be processed. If shutdown is
+// true, the caller should end its processing loop; the returned item is
+// meaningless in that case.
+func (q *Type) Get() (item any, shutdown bool) {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	for len(q.queue) == 0 && !q.shuttingDown {
+		q.cond.Wait()
+	}
+	if len(q.queue) == 0 {
+		return nil, true
+	}
+
+	item = q.queue[0]
+	q.queue[0] = nil
+	q.queue = q.queue[1:]
+
+	q.processing.insert(item)
+	q.dirty.delete(item)
+
+	return item, false
+}
+
+// Done marks item as finished processing. If item was Add-ed again while it
+// was being processed, it is re-queued.
+func (q *Type) Done(item any) {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	q.processing.delete(item)
+	if q.dirty.has(item) {
+		q.queue = append(q.queue, item)
+		q.cond.Signal()
+	} else if q.processing.len() == 0 {
+		q.cond.Signal()
+	}
+}
+
@@ -0,0 +1,143 @@
+package workqueue
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// RateLimiter decides how long an item should wait before being processed
+// again after AddRateLimited.
+type RateLimiter interface {
+	// When returns how long item should wait before being processed.
+	When(item any) time.Duration
+	// Forget drops item's failure history, as though it had never failed.
+	Forget(item any)
+	// NumRequeues returns how many times When has been called for item
+	// since the last Forget. Limiters that keep no per-item history, such
+	// as BucketRateLimiter, always return 0.
+	NumRequeues(item any) int
+}
+
+// BucketRateLimiter rate limits every item identically using a token
+// bucket, ignoring per-item failure history.
+type BucketRateLimiter struct {
+	*rate.Limiter
+}
+
+var _ RateLimiter = &BucketRateLimiter{}
+
+func (r *BucketRateLimiter) When(item any) time.Duration {
+	return r.Limiter.Reserve().Delay()
+}
+
+func (r *BucketRateLimiter) NumRequeues(item any) int {
+	return 0
+}
+
+func (r *BucketRateLimiter) Forget(item any) {
+}
+
+// ItemExponentialFailureRateLimiter tracks failures per item and backs off
+// exponentially: baseDelay * 2^failures, capped at maxDelay.
+type ItemExponentialFailureRateLimiter struct {
+	failuresLock sync.Mutex
+	failures     map[any]int
+
+	baseDelay time.Duration
+	maxDelay  time.Duration
+}
+
+var _ RateLimiter = &ItemExponentialFailureRateLimiter{}
+
+func NewItemExponentialFailureRateLimiter(baseDelay, maxDelay time.Duration) RateLimiter {
+	return &ItemExponentialFailureRateLimiter{
+		failures:  map[any]int{},
+		baseDelay: baseDelay,
+		maxDelay:  maxDelay,
+	}
+}
+
+func DefaultItemBasedRateLimiter() RateLimiter {
+	return NewItemExponentialFailureRateLimiter(time.Millisecond, 1000*time.Second)
+}
+
+func (r *ItemExponentialFailureRateLimiter) When(item any) time.Duration {
+	r.failuresLock.Lock()
+	defer r.failuresLock.Unlock()
+
+	exp := r.failures[item]
+	r.failures[item] = exp + 1
+
+	backoff := float64(r.baseDelay.Nanoseconds()) * math.Pow(2, float64(exp))
+	if backoff > math.MaxInt64 {
+		return r.maxDelay
+	}
+
+	calculated := time.Duration(backoff)
+	if calculated > r.maxDelay {
+		return r.maxDelay
+	}
+	return calculated
+}
+
+func (r *ItemExponentialFailureRateLimiter) NumRequeues(item any) int {
+	r.failuresLock.Lock()
+	defer r.failuresLock.Unlock()
+	return r.failures[item]
+}
+
+func (r *ItemExponentialFailureRateLimiter) Forget(item any) {
+	r.failuresLock.Lock()
+	defer r.failuresLock.Unlock()
+	delete(r.failures, item)
+}
+
+// MaxOfRateLimiter calls every limiter and returns the largest delay, so
+// the slowest limiter always wins.
+type MaxOfRateLimiter struct {
+	limiters []RateLimiter
+}
+
+var _ RateLimiter = &MaxOfRateLimiter{}
+
+func NewMaxOfRateLimiter(limiters ...RateLimiter) RateLimiter {
+	return &MaxOfRateLimiter{limiters: limiters}
+}
+
+func (r *MaxOfRateLimiter) When(item any) time.Duration {
+	ret := time.Duration(0)
+	for _, limiter := range r.limiters {
+		if curr := limiter.When(item); curr > ret {
+			ret = curr
+		}
+	}
+	return ret
+}
+
+func (r *MaxOfRateLimiter) NumRequeues(item any) int {
+	ret := 0
+	for _, limiter := range r.limiters {
+		if curr := limiter.NumRequeues(item); curr > ret {
+			ret = curr
+		}
+	}
+	return ret
+}
+
+func (r *MaxOfRateLimiter) Forget(item any) {
+	for _, limiter := range r.limiters {
+		limiter.Forget(item)
+	}
+}
+
+// DefaultControllerRateLimiter matches what most Kubernetes controllers
+// use: per-item exponential backoff, capped by an overall token bucket.
+func DefaultControllerRateLimiter() RateLimiter {
+	return NewMaxOfRateLimiter(
+		NewItemExponentialFailureRateLimiter(5*time.Millisecond, 1000*time.Second),
+		&BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(10), 100)},
+	)
+}
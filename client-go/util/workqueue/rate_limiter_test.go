@@ -0,0 +1,63 @@
+package workqueue_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/GRTheory/k8s-learn/client-go/util/workqueue"
+)
+
+func TestItemExponentialFailureRateLimiter(t *testing.T) {
+	limiter := workqueue.NewItemExponentialFailureRateLimiter(1*time.Millisecond, 100*time.Millisecond)
+
+	if d := limiter.When("one"); d != 1*time.Millisecond {
+		t.Errorf("expected 1ms, got %v", d)
+	}
+	if d := limiter.When("one"); d != 2*time.Millisecond {
+		t.Errorf("expected 2ms, got %v", d)
+	}
+	if d := limiter.When("one"); d != 4*time.Millisecond {
+		t.Errorf("expected 4ms, got %v", d)
+	}
+	if n := limiter.NumRequeues("one"); n != 3 {
+		t.Errorf("expected 3 requeues, got %d", n)
+	}
+
+	limiter.Forget("one")
+	if n := limiter.NumRequeues("one"); n != 0 {
+		t.Errorf("expected 0 requeues after Forget, got %d", n)
+	}
+	if d := limiter.When("one"); d != 1*time.Millisecond {
+		t.Errorf("expected backoff to restart at 1ms after Forget, got %v", d)
+	}
+}
+
+func TestItemExponentialFailureRateLimiterCapsAtMaxDelay(t *testing.T) {
+	limiter := workqueue.NewItemExponentialFailureRateLimiter(1*time.Millisecond, 5*time.Millisecond)
+
+	var last time.Duration
+	for i := 0; i < 10; i++ {
+		last = limiter.When("one")
+	}
+	if last != 5*time.Millisecond {
+		t.Errorf("expected backoff to cap at 5ms, got %v", last)
+	}
+}
+
+func TestMaxOfRateLimiter(t *testing.T) {
+	fast := workqueue.NewItemExponentialFailureRateLimiter(1*time.Millisecond, time.Second)
+	slow := workqueue.NewItemExponentialFailureRateLimiter(100*time.Millisecond, time.Second)
+	limiter := workqueue.NewMaxOfRateLimiter(fast, slow)
+
+	if d := limiter.When("item"); d != 100*time.Millisecond {
+		t.Errorf("expected the slower limiter's delay (100ms), got %v", d)
+	}
+	if n := limiter.NumRequeues("item"); n != 1 {
+		t.Errorf("expected 1 requeue, got %d", n)
+	}
+
+	limiter.Forget("item")
+	if n := limiter.NumRequeues("item"); n != 0 {
+		t.Errorf("expected 0 requeues after Forget, got %d", n)
+	}
+}
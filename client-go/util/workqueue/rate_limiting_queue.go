@@ -0,0 +1,44 @@
+package workqueue
+
+// RateLimitingInterface extends DelayingInterface with a way to add items
+// back after a rate-limiter-chosen delay, and to inspect or clear their
+// failure history.
+type RateLimitingInterface interface {
+	DelayingInterface
+
+	// AddRateLimited adds item after rateLimiter.When(item).
+	AddRateLimited(item any)
+	// Forget drops item's rate limiter failure history, as though it had
+	// never failed. It does not remove item from the queue.
+	Forget(item any)
+	// NumRequeues returns the rate limiter's NumRequeues for item: how many
+	// times it has been added via AddRateLimited since the last Forget, or
+	// always 0 for a limiter that keeps no per-item history.
+	NumRequeues(item any) int
+}
+
+// NewRateLimitingQueue constructs a RateLimitingInterface backed by a
+// DelayingInterface, using rateLimiter to compute AddRateLimited's delay.
+func NewRateLimitingQueue(rateLimiter RateLimiter) RateLimitingInterface {
+	return &rateLimitingType{
+		DelayingInterface: NewDelayingQueue(),
+		rateLimiter:       rateLimiter,
+	}
+}
+
+type rateLimitingType struct {
+	DelayingInterface
+	rateLimiter RateLimiter
+}
+
+func (q *rateLimitingType) AddRateLimited(item any) {
+	q.DelayingInterface.AddAfter(item, q.rateLimiter.When(item))
+}
+
+func (q *rateLimitingType) NumRequeues(item any) int {
+	return q.rateLimiter.NumRequeues(item)
+}
+
+func (q *rateLimitingType) Forget(item any) {
+	q.rateLimiter.Forget(item)
+}
@@ -0,0 +1,32 @@
+package workqueue_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/GRTheory/k8s-learn/client-go/util/workqueue"
+)
+
+func TestRateLimitingQueueAddRateLimited(t *testing.T) {
+	q := workqueue.NewRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(5*time.Millisecond, time.Second))
+	defer q.ShutDown()
+
+	q.AddRateLimited("item")
+	if n := q.NumRequeues("item"); n != 1 {
+		t.Errorf("expected 1 requeue, got %d", n)
+	}
+
+	item, shutdown := q.Get()
+	if shutdown {
+		t.Fatal("unexpected shutdown")
+	}
+	if item != "item" {
+		t.Fatalf("expected \"item\", got %v", item)
+	}
+	q.Done(item)
+
+	q.Forget("item")
+	if n := q.NumRequeues("item"); n != 0 {
+		t.Errorf("expected 0 requeues after Forget, got %d", n)
+	}
+}